@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"runtime"
+	"strings"
 
 	"gopkg.in/yaml.v2"
 )
@@ -12,12 +13,28 @@ import (
 type APISite struct {
 	Name        string `yaml:"name"`
 	BaseURL     string `yaml:"base_url"`
-	Token       string `yaml:"token"`
+	Token       string `yaml:"token,omitempty"`
+	// Group optionally buckets related sites (e.g. "work", "personal") so
+	// `chcc use`/`chcc list` can filter on it. Empty for existing configs.
+	Group string `yaml:"group,omitempty"`
+	// Tags are free-form labels used the same way as Group, but a site can
+	// carry several of them.
+	Tags []string `yaml:"tags,omitempty"`
 }
 
 type Config struct {
 	APISites       []APISite `yaml:"api_sites"`
 	DefaultAPISite string    `yaml:"default_api_site"`
+	// Storage selects where tokens live: "plaintext" (default, kept in
+	// APISites above) or "keyring" (stored in the OS credential store and
+	// omitted from the YAML file). See tokenstore.go.
+	Storage string `yaml:"storage,omitempty"`
+	// ListenSocket overrides the Unix domain socket (named pipe on Windows)
+	// path used by `chcc serve`. Defaults to ~/.chcc.sock. See serve.go.
+	ListenSocket string `yaml:"listen_socket,omitempty"`
+	// ListenAddr optionally adds a TCP listener alongside the socket, for
+	// setups where a local socket isn't reachable (e.g. containers).
+	ListenAddr string `yaml:"listen_addr,omitempty"`
 }
 
 func LoadConfig(filename string) (*Config, error) {
@@ -56,16 +73,6 @@ func (c *Config) GetAPISiteByName(name string) *APISite {
 	return nil
 }
 
-func (c *Config) PrintConfig() {
-	fmt.Printf("Default API Site: %s\n", c.DefaultAPISite)
-	fmt.Println("Available API Sites:")
-	for i, site := range c.APISites {
-		fmt.Printf("  %d. %s\n", i+1, site.Name)
-		fmt.Printf("     URL: %s\n", site.BaseURL)
-		fmt.Printf("     Token: %s...\n", site.Token[:min(len(site.Token), 20)])
-	}
-}
-
 func (c *Config) SaveConfig(filename string) error {
 	data, err := yaml.Marshal(c)
 	if err != nil {
@@ -80,21 +87,26 @@ func (c *Config) SaveConfig(filename string) error {
 	return nil
 }
 
-func (c *Config) AddOrUpdateAPISite(name, baseURL, token string) {
+func (c *Config) AddOrUpdateAPISite(name, baseURL, token, group string, tags []string) error {
+	found := false
 	for i, site := range c.APISites {
 		if site.Name == name {
 			c.APISites[i].BaseURL = baseURL
-			c.APISites[i].Token = token
-			return
+			c.APISites[i].Group = group
+			c.APISites[i].Tags = tags
+			found = true
+			break
 		}
 	}
-	
-	newSite := APISite{
-		Name:    name,
-		BaseURL: baseURL,
-		Token:   token,
+
+	if !found {
+		c.APISites = append(c.APISites, APISite{Name: name, BaseURL: baseURL, Group: group, Tags: tags})
+	}
+
+	if err := c.tokenStore().Set(name, token); err != nil {
+		return err
 	}
-	c.APISites = append(c.APISites, newSite)
+	return nil
 }
 
 func (c *Config) SetDefaultAPISite(name string) bool {
@@ -107,6 +119,12 @@ func (c *Config) SetDefaultAPISite(name string) bool {
 	return false
 }
 
+// SetEnvironmentVariables persists a site's URL/token into the user's rc
+// files (Unix) or registry (Windows), in addition to the current process's
+// environment. This mutates shell init files, so callers should only invoke
+// it behind an explicit opt-in (set-default --persist); prefer `chcc env` /
+// `chcc shell-init` for applying changes to the current shell without
+// touching rc files at all. See envfmt.go and shellinit.go.
 func (c *Config) SetEnvironmentVariables(siteName string) error {
 	site := c.GetAPISiteByName(siteName)
 	if site == nil {
@@ -114,7 +132,10 @@ func (c *Config) SetEnvironmentVariables(siteName string) error {
 	}
 
 	baseURL := site.BaseURL
-	authToken := site.Token
+	authToken, err := c.tokenStore().Get(siteName)
+	if err != nil {
+		return err
+	}
 
 	if runtime.GOOS == "windows" {
 		return setWindowsEnvVars(baseURL, authToken)
@@ -142,6 +163,22 @@ func setWindowsEnvVars(baseURL, authToken string) error {
 	return nil
 }
 
+// chccPersistBlockStart and chccPersistBlockEnd delimit the managed export
+// block that setUnixEnvVars maintains in the user's rc files. Keeping the
+// block delimited lets repeated `set-default --persist` invocations replace
+// it in place instead of appending a fresh, stale copy every time.
+//
+// chccShellInitBlockStart and chccShellInitBlockEnd (shellinit.go) delimit
+// the separate chcc() wrapper block that `chcc shell-init` prints. The two
+// kinds of block use distinct markers on purpose: they're mutually
+// exclusive ways of applying `set-default`/`use` changes to a shell, and
+// sharing one marker pair would let whichever ran most recently silently
+// delete the other.
+const (
+	chccPersistBlockStart = "# >>> chcc:persist >>>"
+	chccPersistBlockEnd   = "# <<< chcc:persist <<<"
+)
+
 func setUnixEnvVars(baseURL, authToken string) error {
 	// 设置当前进程环境变量
 	os.Setenv("ANTHROPIC_BASE_URL", baseURL)
@@ -156,28 +193,91 @@ func setUnixEnvVars(baseURL, authToken string) error {
 	bashrcPath := homeDir + "/.bashrc"
 	zshrcPath := homeDir + "/.zshrc"
 
-	exportLines := fmt.Sprintf("\n# CHCC API Configuration\nexport ANTHROPIC_BASE_URL=\"%s\"\nexport ANTHROPIC_AUTH_TOKEN=\"%s\"\n", baseURL, authToken)
+	block := fmt.Sprintf("%s\nexport ANTHROPIC_BASE_URL=\"%s\"\nexport ANTHROPIC_AUTH_TOKEN=\"%s\"\n%s\n",
+		chccPersistBlockStart, baseURL, authToken, chccPersistBlockEnd)
 
 	for _, rcFile := range []string{bashrcPath, zshrcPath} {
-		if _, err := os.Stat(rcFile); err == nil {
-			f, err := os.OpenFile(rcFile, os.O_APPEND|os.O_WRONLY, 0644)
-			if err != nil {
-				continue
-			}
-			f.WriteString(exportLines)
-			f.Close()
+		if _, err := os.Stat(rcFile); err != nil {
+			continue
+		}
+		if err := writeManagedBlock(rcFile, chccPersistBlockStart, chccPersistBlockEnd, block); err != nil {
+			fmt.Printf("Warning: failed to update %s: %v\n", rcFile, err)
 		}
 	}
 
 	return nil
 }
 
+// writeManagedBlock replaces the existing block delimited by start/end in
+// rcFile, or appends block as a new one if none is present yet. Callers pass
+// the marker pair for the specific kind of block they own (e.g.
+// chccPersistBlockStart/End) so distinct block kinds never clobber each
+// other.
+func writeManagedBlock(rcFile, start, end, block string) error {
+	data, err := os.ReadFile(rcFile)
+	if err != nil {
+		return err
+	}
+	content := string(data)
+
+	startIdx := strings.Index(content, start)
+	endIdx := strings.Index(content, end)
+
+	var updated string
+	if startIdx != -1 && endIdx != -1 && endIdx > startIdx {
+		updated = content[:startIdx] + block + trimLeadingNewline(content[endIdx+len(end):])
+	} else {
+		if len(content) > 0 && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		updated = content + "\n" + block
+	}
+
+	return os.WriteFile(rcFile, []byte(updated), 0644)
+}
+
+// trimLeadingNewline strips a single leading "\n" from s, if present. The
+// managed-block helpers use this on the remainder after an end marker, since
+// that remainder still starts with the "\n" that terminated the marker's own
+// line and block/removal already supplies (or intends to leave) that
+// newline itself.
+func trimLeadingNewline(s string) string {
+	return strings.TrimPrefix(s, "\n")
+}
+
+// removeManagedBlock strips the block delimited by start/end from rcFile if
+// present, for `chcc uninstall-shell-hooks`. removed is false (with a nil
+// error) when rcFile has no such block to remove.
+func removeManagedBlock(rcFile, start, end string) (removed bool, err error) {
+	data, err := os.ReadFile(rcFile)
+	if err != nil {
+		return false, err
+	}
+	content := string(data)
+
+	startIdx := strings.Index(content, start)
+	endIdx := strings.Index(content, end)
+	if startIdx == -1 || endIdx == -1 || endIdx < startIdx {
+		return false, nil
+	}
+
+	updated := content[:startIdx] + trimLeadingNewline(content[endIdx+len(end):])
+	if err := os.WriteFile(rcFile, []byte(updated), 0644); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 func (c *Config) RemoveAPISite(name string) bool {
 	for i, site := range c.APISites {
 		if site.Name == name {
+			if err := c.tokenStore().Delete(name); err != nil {
+				fmt.Printf("Warning: failed to delete stored token for %s: %v\n", name, err)
+			}
+
 			// 删除该站点
 			c.APISites = append(c.APISites[:i], c.APISites[i+1:]...)
-			
+
 			// 如果删除的是默认站点，清空默认设置
 			if c.DefaultAPISite == name {
 				c.DefaultAPISite = ""
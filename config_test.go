@@ -0,0 +1,121 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTempRCFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rcfile")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write temp rc file: %v", err)
+	}
+	return path
+}
+
+func readRCFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read rc file: %v", err)
+	}
+	return string(data)
+}
+
+func TestWriteManagedBlockAppendsWhenAbsent(t *testing.T) {
+	path := writeTempRCFile(t, "# existing rc content\n")
+
+	block := chccPersistBlockStart + "\nexport FOO=bar\n" + chccPersistBlockEnd + "\n"
+	if err := writeManagedBlock(path, chccPersistBlockStart, chccPersistBlockEnd, block); err != nil {
+		t.Fatalf("writeManagedBlock returned error: %v", err)
+	}
+
+	got := readRCFile(t, path)
+	want := "# existing rc content\n\n" + block
+	if got != want {
+		t.Errorf("writeManagedBlock appended %q, want %q", got, want)
+	}
+}
+
+func TestWriteManagedBlockReplacesInPlace(t *testing.T) {
+	oldBlock := chccPersistBlockStart + "\nexport FOO=old\n" + chccPersistBlockEnd + "\n"
+	path := writeTempRCFile(t, "before\n"+oldBlock+"after\n")
+
+	newBlock := chccPersistBlockStart + "\nexport FOO=new\n" + chccPersistBlockEnd + "\n"
+	if err := writeManagedBlock(path, chccPersistBlockStart, chccPersistBlockEnd, newBlock); err != nil {
+		t.Fatalf("writeManagedBlock returned error: %v", err)
+	}
+
+	got := readRCFile(t, path)
+	want := "before\n" + newBlock + "after\n"
+	if got != want {
+		t.Errorf("writeManagedBlock = %q, want %q", got, want)
+	}
+}
+
+func TestWriteManagedBlockDoesNotTouchOtherBlockKind(t *testing.T) {
+	shellInitBlock := chccShellInitBlockStart + "\nchcc() { command chcc \"$@\"; }\n" + chccShellInitBlockEnd + "\n"
+	path := writeTempRCFile(t, shellInitBlock)
+
+	persistBlock := chccPersistBlockStart + "\nexport FOO=bar\n" + chccPersistBlockEnd + "\n"
+	if err := writeManagedBlock(path, chccPersistBlockStart, chccPersistBlockEnd, persistBlock); err != nil {
+		t.Fatalf("writeManagedBlock returned error: %v", err)
+	}
+
+	got := readRCFile(t, path)
+	if !strings.Contains(got, shellInitBlock) {
+		t.Errorf("writeManagedBlock for the persist block removed the shell-init block: %q", got)
+	}
+	if !strings.Contains(got, persistBlock) {
+		t.Errorf("writeManagedBlock did not add the persist block: %q", got)
+	}
+}
+
+func TestRemoveManagedBlock(t *testing.T) {
+	block := chccPersistBlockStart + "\nexport FOO=bar\n" + chccPersistBlockEnd + "\n"
+	path := writeTempRCFile(t, "before\n"+block+"after\n")
+
+	removed, err := removeManagedBlock(path, chccPersistBlockStart, chccPersistBlockEnd)
+	if err != nil {
+		t.Fatalf("removeManagedBlock returned error: %v", err)
+	}
+	if !removed {
+		t.Fatal("removeManagedBlock reported nothing removed")
+	}
+
+	got := readRCFile(t, path)
+	if got != "before\nafter\n" {
+		t.Errorf("removeManagedBlock left %q, want %q", got, "before\nafter\n")
+	}
+}
+
+func TestWriteManagedBlockRepeatedInvocationsDoNotAccumulateBlankLines(t *testing.T) {
+	path := writeTempRCFile(t, "before\nafter\n")
+
+	block := chccPersistBlockStart + "\nexport FOO=bar\n" + chccPersistBlockEnd + "\n"
+	want := "before\nafter\n\n" + block
+	for i := 0; i < 3; i++ {
+		if err := writeManagedBlock(path, chccPersistBlockStart, chccPersistBlockEnd, block); err != nil {
+			t.Fatalf("writeManagedBlock returned error on iteration %d: %v", i, err)
+		}
+		got := readRCFile(t, path)
+		if got != want {
+			t.Fatalf("after %d writeManagedBlock calls, got %q, want %q (no stray blank lines should accumulate)", i+1, got, want)
+		}
+	}
+}
+
+func TestRemoveManagedBlockNoBlockPresent(t *testing.T) {
+	path := writeTempRCFile(t, "just some rc content\n")
+
+	removed, err := removeManagedBlock(path, chccPersistBlockStart, chccPersistBlockEnd)
+	if err != nil {
+		t.Fatalf("removeManagedBlock returned error: %v", err)
+	}
+	if removed {
+		t.Error("removeManagedBlock reported a removal when no block was present")
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	envFormatBash       = "bash"
+	envFormatZsh        = "zsh"
+	envFormatFish       = "fish"
+	envFormatPowerShell = "powershell"
+	envFormatCmd        = "cmd"
+	envFormatJSON       = "json"
+	envFormatDotenv     = "dotenv"
+)
+
+// envVars is the pair of environment variables chcc manages.
+type envVars struct {
+	BaseURL   string
+	AuthToken string
+}
+
+// posixQuote single-quotes s for bash/zsh (and for fish, once backslashes in
+// s have been doubled; see formatEnv), closing the quote, inserting
+// a backslash-escaped quote, then reopening it around each embedded single
+// quote, so the result is safe to pass to `eval` even when s contains
+// $(...), backticks, or other shell metacharacters. Go's %q is not safe here:
+// it produces a double-quoted string, and bash/zsh/fish still expand
+// $(...)/backticks inside double quotes.
+func posixQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// powershellQuote single-quotes s for PowerShell, escaping embedded single
+// quotes by doubling them, so the result is safe even when s contains
+// $(...)  or other characters PowerShell would otherwise expand.
+func powershellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// formatEnv renders vars in the syntax for the given shell/format, so
+// `chcc env` and the chcc shell-init wrappers can stay in sync. An empty
+// format behaves like "bash".
+func formatEnv(vars envVars, format string) (string, error) {
+	switch format {
+	case "", envFormatBash, envFormatZsh:
+		return fmt.Sprintf("export ANTHROPIC_BASE_URL=%s\nexport ANTHROPIC_AUTH_TOKEN=%s\n", posixQuote(vars.BaseURL), posixQuote(vars.AuthToken)), nil
+	case envFormatFish:
+		// fish treats \\ and \' as escapes even inside single quotes, so
+		// backslashes are doubled before quoting.
+		fishEscape := func(s string) string { return posixQuote(strings.ReplaceAll(s, `\`, `\\`)) }
+		return fmt.Sprintf("set -gx ANTHROPIC_BASE_URL %s\nset -gx ANTHROPIC_AUTH_TOKEN %s\n", fishEscape(vars.BaseURL), fishEscape(vars.AuthToken)), nil
+	case envFormatPowerShell:
+		return fmt.Sprintf("$env:ANTHROPIC_BASE_URL = %s\n$env:ANTHROPIC_AUTH_TOKEN = %s\n", powershellQuote(vars.BaseURL), powershellQuote(vars.AuthToken)), nil
+	case envFormatCmd:
+		return fmt.Sprintf("set ANTHROPIC_BASE_URL=%s\r\nset ANTHROPIC_AUTH_TOKEN=%s\r\n", vars.BaseURL, vars.AuthToken), nil
+	case envFormatDotenv:
+		return fmt.Sprintf("ANTHROPIC_BASE_URL=%s\nANTHROPIC_AUTH_TOKEN=%s\n", vars.BaseURL, vars.AuthToken), nil
+	case envFormatJSON:
+		data, err := json.MarshalIndent(map[string]string{
+			"ANTHROPIC_BASE_URL":   vars.BaseURL,
+			"ANTHROPIC_AUTH_TOKEN": vars.AuthToken,
+		}, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(data) + "\n", nil
+	default:
+		return "", fmt.Errorf("unknown env format %q (want bash, zsh, fish, powershell, cmd, json, or dotenv)", format)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import "testing"
+
+func TestFormatEnvQuotesInjectionAttempts(t *testing.T) {
+	vars := envVars{
+		BaseURL:   "https://example.com",
+		AuthToken: "abc$(touch /tmp/chcc-test-pwned)`touch /tmp/chcc-test-pwned2`",
+	}
+
+	for _, format := range []string{"", envFormatBash, envFormatZsh, envFormatFish} {
+		out, err := formatEnv(vars, format)
+		if err != nil {
+			t.Fatalf("formatEnv(%q) returned error: %v", format, err)
+		}
+		if containsUnescapedShellExpansion(out) {
+			t.Errorf("formatEnv(%q) = %q is not safe for eval: contains an unescaped $() or backtick expansion", format, out)
+		}
+	}
+
+	out, err := formatEnv(vars, envFormatPowerShell)
+	if err != nil {
+		t.Fatalf("formatEnv(powershell) returned error: %v", err)
+	}
+	want := "$env:ANTHROPIC_BASE_URL = 'https://example.com'\n$env:ANTHROPIC_AUTH_TOKEN = 'abc$(touch /tmp/chcc-test-pwned)`touch /tmp/chcc-test-pwned2`'\n"
+	if out != want {
+		t.Errorf("formatEnv(powershell) = %q, want %q", out, want)
+	}
+}
+
+// containsUnescapedShellExpansion reports whether s contains a $(...) or
+// backtick outside single quotes, i.e. one that bash/zsh/fish would still
+// expand.
+func containsUnescapedShellExpansion(s string) bool {
+	inSingleQuotes := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\'':
+			inSingleQuotes = !inSingleQuotes
+		case inSingleQuotes:
+			continue
+		case s[i] == '`':
+			return true
+		case s[i] == '$' && i+1 < len(s) && s[i+1] == '(':
+			return true
+		}
+	}
+	return false
+}
+
+func TestFormatEnvEmbeddedSingleQuote(t *testing.T) {
+	vars := envVars{BaseURL: "https://example.com", AuthToken: "it's-a-token"}
+
+	out, err := formatEnv(vars, envFormatBash)
+	if err != nil {
+		t.Fatalf("formatEnv returned error: %v", err)
+	}
+	want := "export ANTHROPIC_BASE_URL='https://example.com'\nexport ANTHROPIC_AUTH_TOKEN='it'\\''s-a-token'\n"
+	if out != want {
+		t.Errorf("formatEnv(bash) = %q, want %q", out, want)
+	}
+
+	out, err = formatEnv(vars, envFormatPowerShell)
+	if err != nil {
+		t.Fatalf("formatEnv returned error: %v", err)
+	}
+	want = "$env:ANTHROPIC_BASE_URL = 'https://example.com'\n$env:ANTHROPIC_AUTH_TOKEN = 'it''s-a-token'\n"
+	if out != want {
+		t.Errorf("formatEnv(powershell) = %q, want %q", out, want)
+	}
+}
+
+func TestFormatEnvUnknownFormat(t *testing.T) {
+	if _, err := formatEnv(envVars{}, "tcsh"); err == nil {
+		t.Error("formatEnv with an unknown format should return an error")
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputCSV   = "csv"
+)
+
+// filterSites returns the sites matching tag and group, in their original
+// order. An empty tag or group means "don't filter on this dimension", so
+// filterSites(sites, "", "") returns sites unchanged.
+func filterSites(sites []APISite, tag, group string) []APISite {
+	if tag == "" && group == "" {
+		return sites
+	}
+
+	var out []APISite
+	for _, site := range sites {
+		if group != "" && site.Group != group {
+			continue
+		}
+		if tag != "" && !containsString(site.Tags, tag) {
+			continue
+		}
+		out = append(out, site)
+	}
+	return out
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+// apiSiteJSON is the machine-readable shape emitted by `chcc list --output
+// json`. Tokens are deliberately left out of machine output. Healthy is nil
+// unless `chcc list --check` was passed.
+type apiSiteJSON struct {
+	Name    string   `json:"name"`
+	BaseURL string   `json:"base_url"`
+	Group   string   `json:"group,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+	Default bool     `json:"default"`
+	Healthy *bool    `json:"healthy,omitempty"`
+}
+
+// renderSites prints sites in the requested format ("table", "json", or
+// "csv"; "" behaves like "table"). store is only consulted for the table
+// format, which shows a masked token preview. statuses is the result of
+// `chcc list --check` (nil if --check wasn't passed) and is threaded into
+// every format so the flag isn't silently dropped for json/csv.
+func renderSites(sites []APISite, defaultName, format string, store TokenStore, statuses map[string]bool) error {
+	switch format {
+	case "", outputTable:
+		renderSitesTable(sites, defaultName, store, statuses)
+		return nil
+	case outputJSON:
+		return renderSitesJSON(sites, defaultName, statuses)
+	case outputCSV:
+		return renderSitesCSV(sites, defaultName, statuses)
+	default:
+		return fmt.Errorf("unknown output format %q (want table, json, or csv)", format)
+	}
+}
+
+// renderSitesTable prints the human-readable site listing. When statuses is
+// non-nil, each site gets a green/red health marker from `chcc list --check`
+// (a missing entry means that site wasn't checked).
+func renderSitesTable(sites []APISite, defaultName string, store TokenStore, statuses map[string]bool) {
+	for i, site := range sites {
+		marker := " "
+		if site.Name == defaultName {
+			marker = "*"
+		}
+		fmt.Printf("%s %d. %s%s\n", marker, i+1, site.Name, healthMarker(statuses, site.Name))
+		fmt.Printf("      URL: %s\n", site.BaseURL)
+		if site.Group != "" {
+			fmt.Printf("      Group: %s\n", site.Group)
+		}
+		if len(site.Tags) > 0 {
+			fmt.Printf("      Tags: %s\n", strings.Join(site.Tags, ", "))
+		}
+		token, err := store.Get(site.Name)
+		if err != nil {
+			token = site.Token
+		}
+		fmt.Printf("      Token: %s...\n", token[:min(len(token), 20)])
+	}
+}
+
+// healthMarker renders a green/red dot for `chcc list --check`, or nothing
+// if statuses is nil (the common case when --check wasn't passed) or the
+// site isn't in it.
+func healthMarker(statuses map[string]bool, siteName string) string {
+	if statuses == nil {
+		return ""
+	}
+	healthy, checked := statuses[siteName]
+	if !checked {
+		return ""
+	}
+	if healthy {
+		return " \033[32m●\033[0m"
+	}
+	return " \033[31m●\033[0m"
+}
+
+func renderSitesJSON(sites []APISite, defaultName string, statuses map[string]bool) error {
+	out := make([]apiSiteJSON, 0, len(sites))
+	for _, site := range sites {
+		entry := apiSiteJSON{
+			Name:    site.Name,
+			BaseURL: site.BaseURL,
+			Group:   site.Group,
+			Tags:    site.Tags,
+			Default: site.Name == defaultName,
+		}
+		if healthy, checked := statuses[site.Name]; checked {
+			entry.Healthy = &healthy
+		}
+		out = append(out, entry)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
+
+func renderSitesCSV(sites []APISite, defaultName string, statuses map[string]bool) error {
+	writer := csv.NewWriter(os.Stdout)
+	defer writer.Flush()
+
+	if err := writer.Write([]string{"name", "base_url", "group", "tags", "default", "healthy"}); err != nil {
+		return err
+	}
+
+	for _, site := range sites {
+		healthyCol := ""
+		if healthy, checked := statuses[site.Name]; checked {
+			healthyCol = fmt.Sprintf("%t", healthy)
+		}
+		row := []string{
+			site.Name,
+			site.BaseURL,
+			site.Group,
+			strings.Join(site.Tags, "|"),
+			fmt.Sprintf("%t", site.Name == defaultName),
+			healthyCol,
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
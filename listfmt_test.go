@@ -0,0 +1,60 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilterSitesNoFilter(t *testing.T) {
+	sites := []APISite{{Name: "a"}, {Name: "b"}}
+	got := filterSites(sites, "", "")
+	if !reflect.DeepEqual(got, sites) {
+		t.Errorf("filterSites with no tag/group = %v, want sites unchanged: %v", got, sites)
+	}
+}
+
+func TestFilterSitesByGroup(t *testing.T) {
+	sites := []APISite{
+		{Name: "a", Group: "work"},
+		{Name: "b", Group: "personal"},
+		{Name: "c", Group: "work"},
+	}
+	got := filterSites(sites, "", "work")
+	want := []APISite{{Name: "a", Group: "work"}, {Name: "c", Group: "work"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("filterSites(group=work) = %v, want %v", got, want)
+	}
+}
+
+func TestFilterSitesByTag(t *testing.T) {
+	sites := []APISite{
+		{Name: "a", Tags: []string{"fast", "prod"}},
+		{Name: "b", Tags: []string{"slow"}},
+	}
+	got := filterSites(sites, "prod", "")
+	want := []APISite{{Name: "a", Tags: []string{"fast", "prod"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("filterSites(tag=prod) = %v, want %v", got, want)
+	}
+}
+
+func TestFilterSitesByTagAndGroup(t *testing.T) {
+	sites := []APISite{
+		{Name: "a", Group: "work", Tags: []string{"prod"}},
+		{Name: "b", Group: "work", Tags: []string{"staging"}},
+		{Name: "c", Group: "personal", Tags: []string{"prod"}},
+	}
+	got := filterSites(sites, "prod", "work")
+	want := []APISite{{Name: "a", Group: "work", Tags: []string{"prod"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("filterSites(tag=prod, group=work) = %v, want %v", got, want)
+	}
+}
+
+func TestFilterSitesNoMatches(t *testing.T) {
+	sites := []APISite{{Name: "a", Group: "work"}}
+	got := filterSites(sites, "", "nonexistent")
+	if len(got) != 0 {
+		t.Errorf("filterSites for a nonexistent group = %v, want empty", got)
+	}
+}
@@ -1,11 +1,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -24,38 +26,64 @@ var rootCmd = &cobra.Command{
 	Long: `A CLI tool for managing API site configurations.
 You can add, update, list API sites and set default sites.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		listAPISites()
+		listAPISites("", "", "", false)
 	},
 }
 
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all API sites",
-	Long:  "Display all configured API sites and show the default site",
+	Long:  "Display all configured API sites and show the default site. --output switches between human-readable table, json, and csv for scripting; --check augments the listing with a health status column.",
 	Run: func(cmd *cobra.Command, args []string) {
-		listAPISites()
+		format, _ := cmd.Flags().GetString("output")
+		tag, _ := cmd.Flags().GetString("tag")
+		group, _ := cmd.Flags().GetString("group")
+		check, _ := cmd.Flags().GetBool("check")
+		listAPISites(format, tag, group, check)
 	},
 }
 
 var addCmd = &cobra.Command{
 	Use:   "add",
 	Short: "Add or update an API site",
-	Long:  "Add a new API site or update an existing one with name, URL, and token",
+	Long:  "Add a new API site or update an existing one with name, URL, token, and optional group/tags",
 	Run: func(cmd *cobra.Command, args []string) {
 		name, _ := cmd.Flags().GetString("name")
 		url, _ := cmd.Flags().GetString("url")
 		token, _ := cmd.Flags().GetString("token")
-		addAPISite(name, url, token)
+		group, _ := cmd.Flags().GetString("group")
+		tagsRaw, _ := cmd.Flags().GetString("tags")
+		addAPISite(name, url, token, group, splitTags(tagsRaw))
 	},
 }
 
 var setDefaultCmd = &cobra.Command{
 	Use:   "set-default",
 	Short: "Set default API site",
-	Long:  "Set a specific API site as the default",
+	Long:  "Set a specific API site as the default, by --name, by a unique --tag/--group match, or automatically via --auto (lowest-latency healthy site).",
 	Run: func(cmd *cobra.Command, args []string) {
 		name, _ := cmd.Flags().GetString("name")
-		setDefaultAPISite(name)
+		tag, _ := cmd.Flags().GetString("tag")
+		group, _ := cmd.Flags().GetString("group")
+		auto, _ := cmd.Flags().GetBool("auto")
+
+		switch {
+		case auto:
+			resolved, ok := resolveSiteByLatency(tag, group)
+			if !ok {
+				return
+			}
+			name = resolved
+		case name == "":
+			resolved, ok := resolveSiteByFilter(tag, group)
+			if !ok {
+				return
+			}
+			name = resolved
+		}
+
+		persist, _ := cmd.Flags().GetBool("persist")
+		setDefaultAPISite(name, persist)
 	},
 }
 
@@ -69,24 +97,217 @@ var removeCmd = &cobra.Command{
 	},
 }
 
+var useCmd = &cobra.Command{
+	Use:   "use",
+	Short: "Interactively pick the default API site",
+	Long:  "Launch a fuzzy picker over configured API sites, optionally narrowed by --tag/--group, and set the chosen site as the default.",
+	Run: func(cmd *cobra.Command, args []string) {
+		tag, _ := cmd.Flags().GetString("tag")
+		group, _ := cmd.Flags().GetString("group")
+		persist, _ := cmd.Flags().GetBool("persist")
+		useAPISite(tag, group, persist)
+	},
+}
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Print the active site's environment variables",
+	Long:  "Print ANTHROPIC_BASE_URL/ANTHROPIC_AUTH_TOKEN for the default (or --name'd) API site in the requested --format, for `eval \"$(chcc env)\"` snippets or the chcc shell-init wrapper. Never touches rc files.",
+	Run: func(cmd *cobra.Command, args []string) {
+		name, _ := cmd.Flags().GetString("name")
+		format, _ := cmd.Flags().GetString("format")
+		printSiteEnv(name, format)
+	},
+}
+
+var shellInitCmd = &cobra.Command{
+	Use:   "shell-init <bash|zsh|fish|powershell|cmd>",
+	Short: "Print a shell snippet for live set-default/use updates",
+	Long:  "Print a chcc() wrapper function for the given shell that applies `chcc set-default`/`chcc use` changes to the current shell immediately via `chcc env`, instead of chcc mutating rc files. Source the output once, e.g. `chcc shell-init zsh >> ~/.zshrc`.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		script, err := shellInitScript(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Print(script)
+	},
+}
+
+var uninstallShellHooksCmd = &cobra.Command{
+	Use:   "uninstall-shell-hooks",
+	Short: "Remove the chcc-managed block from your shell rc files",
+	Long:  "Remove the chcc-managed block(s) from ~/.bashrc and ~/.zshrc: the shell-init wrapper (delimited by '# >>> chcc:shell-init >>>' / '# <<< chcc:shell-init <<<') and/or the persisted exports (delimited by '# >>> chcc:persist >>>' / '# <<< chcc:persist <<<'), whichever are present.",
+	Run: func(cmd *cobra.Command, args []string) {
+		uninstallShellHooks()
+	},
+}
+
+var doctorCmd = &cobra.Command{
+	Use:     "doctor",
+	Aliases: []string{"ping"},
+	Short:   "Check health and latency of configured API sites",
+	Long:    "Perform a real HTTPS request against each configured site's BaseURL and report status code, round-trip latency, TLS certificate expiry, and whether the stored token was accepted.",
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("output")
+		tag, _ := cmd.Flags().GetString("tag")
+		group, _ := cmd.Flags().GetString("group")
+		runDoctor(format, tag, group)
+	},
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the active API site over a local socket",
+	Long:  "Run a long-lived server that exposes the current default API site over a Unix domain socket, so other tools on the same host can read ANTHROPIC_BASE_URL/ANTHROPIC_AUTH_TOKEN without spawning subshells, and get pushed updates when set-default runs.",
+	Run: func(cmd *cobra.Command, args []string) {
+		socket, _ := cmd.Flags().GetString("socket")
+		serve(socket)
+	},
+}
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate plaintext tokens into the OS keyring",
+	Long:  "Move tokens currently stored in plaintext in ~/.chcc.yaml into the OS keyring (Keychain, Credential Manager, or Secret Service/libsecret) and switch storage mode to keyring.",
+	Run: func(cmd *cobra.Command, args []string) {
+		migrateTokens()
+	},
+}
+
 func init() {
 	addCmd.Flags().StringP("name", "n", "", "API site name (required)")
 	addCmd.Flags().StringP("url", "u", "", "API site base URL (required)")
 	addCmd.Flags().StringP("token", "t", "", "API site token (required)")
+	addCmd.Flags().String("group", "", "Optional group label for this site")
+	addCmd.Flags().String("tags", "", "Optional comma-separated tags for this site")
 	addCmd.MarkFlagRequired("name")
 	addCmd.MarkFlagRequired("url")
 	addCmd.MarkFlagRequired("token")
 
-	setDefaultCmd.Flags().StringP("name", "n", "", "API site name (required)")
-	setDefaultCmd.MarkFlagRequired("name")
+	setDefaultCmd.Flags().StringP("name", "n", "", "API site name")
+	setDefaultCmd.Flags().String("tag", "", "Select the site by tag instead of --name (must match exactly one)")
+	setDefaultCmd.Flags().String("group", "", "Select the site by group instead of --name (must match exactly one)")
+	setDefaultCmd.Flags().Bool("auto", false, "Pick the lowest-latency healthy site instead of --name")
+	setDefaultCmd.Flags().Bool("persist", false, "Also write the change into shell rc files/registry (use chcc shell-init instead where possible)")
 
 	removeCmd.Flags().StringP("name", "n", "", "API site name (required)")
 	removeCmd.MarkFlagRequired("name")
 
+	listCmd.Flags().String("output", "table", "Output format: table, json, or csv")
+	listCmd.Flags().String("tag", "", "Only list sites with this tag")
+	listCmd.Flags().String("group", "", "Only list sites in this group")
+	listCmd.Flags().Bool("check", false, "Probe each site and show a health status column")
+
+	useCmd.Flags().String("tag", "", "Only offer sites with this tag")
+	useCmd.Flags().String("group", "", "Only offer sites in this group")
+	useCmd.Flags().Bool("persist", false, "Also write the change into shell rc files/registry (use chcc shell-init instead where possible)")
+
+	doctorCmd.Flags().String("output", "table", "Output format: table or json")
+	doctorCmd.Flags().String("tag", "", "Only check sites with this tag")
+	doctorCmd.Flags().String("group", "", "Only check sites in this group")
+
+	envCmd.Flags().StringP("name", "n", "", "API site name (defaults to the default API site)")
+	envCmd.Flags().String("format", "", "bash, zsh, fish, powershell, cmd, json, or dotenv (default: guessed from $SHELL)")
+
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(addCmd)
 	rootCmd.AddCommand(setDefaultCmd)
 	rootCmd.AddCommand(removeCmd)
+	rootCmd.AddCommand(migrateCmd)
+	rootCmd.AddCommand(useCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(envCmd)
+	rootCmd.AddCommand(shellInitCmd)
+	rootCmd.AddCommand(uninstallShellHooksCmd)
+
+	serveCmd.Flags().String("socket", "", "Unix domain socket path to listen on (default: listen_socket from config, or ~/.chcc.sock)")
+	rootCmd.AddCommand(serveCmd)
+}
+
+// splitTags turns a comma-separated --tags flag value into a clean slice,
+// dropping empty entries produced by leading/trailing/doubled commas.
+func splitTags(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+
+	var tags []string
+	for _, tag := range strings.Split(raw, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// resolveSiteByFilter looks up the single API site matching tag/group, for
+// commands that accept those as an alternative to --name. Prints guidance
+// and returns ok=false when the filters match zero or more than one site.
+func resolveSiteByFilter(tag, group string) (name string, ok bool) {
+	config := loadConfig()
+	matches := filterSites(config.APISites, tag, group)
+
+	switch len(matches) {
+	case 0:
+		fmt.Println("No API site matches the given --tag/--group filters.")
+		return "", false
+	case 1:
+		return matches[0].Name, true
+	default:
+		fmt.Println("Multiple API sites match the given filters; use --name to disambiguate:")
+		for _, site := range matches {
+			fmt.Printf("  - %s\n", site.Name)
+		}
+		return "", false
+	}
+}
+
+// resolveSiteByLatency probes every site matching tag/group and returns the
+// name of the lowest-latency healthy one, for `set-default --auto`.
+func resolveSiteByLatency(tag, group string) (name string, ok bool) {
+	config := loadConfig()
+	sites := filterSites(config.APISites, tag, group)
+	if len(sites) == 0 {
+		fmt.Println("No API site matches the given --tag/--group filters.")
+		return "", false
+	}
+
+	results := NewProber().ProbeAll(context.Background(), sites, config.tokenStore())
+
+	best := -1
+	for i, result := range results {
+		if !result.Healthy() {
+			continue
+		}
+		if best == -1 || result.LatencyMs < results[best].LatencyMs {
+			best = i
+		}
+	}
+
+	if best == -1 {
+		fmt.Println("No healthy API site found among the candidates.")
+		return "", false
+	}
+
+	fmt.Printf("Auto-selected %s (%dms)\n", results[best].Name, results[best].LatencyMs)
+	return results[best].Name, true
+}
+
+func runDoctor(format, tag, group string) {
+	config := loadConfig()
+	sites := filterSites(config.APISites, tag, group)
+	if len(sites) == 0 {
+		fmt.Println("No API sites match the given filters.")
+		return
+	}
+
+	results := NewProber().ProbeAll(context.Background(), sites, config.tokenStore())
+
+	if err := renderProbeResults(results, format); err != nil {
+		log.Fatalf("Error rendering output: %v", err)
+	}
 }
 
 func main() {
@@ -116,31 +337,54 @@ func loadConfig() *Config {
 	return config
 }
 
-func listAPISites() {
+func listAPISites(format, tag, group string, check bool) {
 	config := loadConfig()
-	
-	if len(config.APISites) == 0 {
+
+	sites := filterSites(config.APISites, tag, group)
+	if len(sites) == 0 {
 		fmt.Println("No API sites configured.")
 		fmt.Println("\nUse 'chcc add --help' to add your first API site.")
 		return
 	}
 
+	var statuses map[string]bool
+	if check {
+		statuses = make(map[string]bool, len(sites))
+		for _, result := range NewProber().ProbeAll(context.Background(), sites, config.tokenStore()) {
+			statuses[result.Name] = result.Healthy()
+		}
+	}
+
+	if format != "" && format != outputTable {
+		if err := renderSites(sites, config.DefaultAPISite, format, config.tokenStore(), statuses); err != nil {
+			log.Fatalf("Error rendering output: %v", err)
+		}
+		return
+	}
+
 	fmt.Println("=== CHCC Configuration ===")
-	config.PrintConfig()
+	fmt.Printf("Default API Site: %s\n", config.DefaultAPISite)
+	fmt.Printf("Token Storage: %s\n", config.storageMode())
+	fmt.Println("Available API Sites:")
+	renderSitesTable(sites, config.DefaultAPISite, config.tokenStore(), statuses)
 
 	fmt.Println("\n=== Default API Site ===")
 	defaultSite := config.GetDefaultAPISite()
 	if defaultSite != nil {
+		token, err := config.tokenStore().Get(defaultSite.Name)
+		if err != nil {
+			token = defaultSite.Token
+		}
 		fmt.Printf("Name: %s\n", defaultSite.Name)
 		fmt.Printf("URL: %s\n", defaultSite.BaseURL)
-		fmt.Printf("Token: %s...\n", defaultSite.Token[:min(len(defaultSite.Token), 10)])
+		fmt.Printf("Token: %s...\n", token[:min(len(token), 10)])
 	} else {
 		fmt.Println("No default API site set")
 		fmt.Println("Use 'chcc set-default --name <site-name>' to set a default site.")
 	}
 }
 
-func addAPISite(name, url, token string) {
+func addAPISite(name, url, token, group string, tags []string) {
 	config := loadConfig()
 
 	existing := config.GetAPISiteByName(name)
@@ -150,7 +394,9 @@ func addAPISite(name, url, token string) {
 		fmt.Printf("Adding new API site: %s\n", name)
 	}
 
-	config.AddOrUpdateAPISite(name, url, token)
+	if err := config.AddOrUpdateAPISite(name, url, token, group, tags); err != nil {
+		log.Fatalf("Error storing token: %v", err)
+	}
 
 	if config.DefaultAPISite == "" {
 		config.DefaultAPISite = name
@@ -166,50 +412,66 @@ func addAPISite(name, url, token string) {
 	fmt.Println("Configuration saved successfully!")
 }
 
-func setDefaultAPISite(name string) {
+func useAPISite(tag, group string, persist bool) {
+	config := loadConfig()
+	sites := filterSites(config.APISites, tag, group)
+
+	chosen := runUsePicker(sites)
+	if chosen == nil {
+		fmt.Println("No site selected.")
+		return
+	}
+
+	setDefaultAPISite(chosen.Name, persist)
+}
+
+// defaultShellFormat guesses the current shell for formatting `chcc env`
+// output when --format wasn't given, from $SHELL (Unix) or the OS (Windows).
+func defaultShellFormat() string {
+	if runtime.GOOS == "windows" {
+		return envFormatPowerShell
+	}
+	switch {
+	case strings.Contains(os.Getenv("SHELL"), "fish"):
+		return envFormatFish
+	case strings.Contains(os.Getenv("SHELL"), "zsh"):
+		return envFormatZsh
+	default:
+		return envFormatBash
+	}
+}
+
+func setDefaultAPISite(name string, persist bool) {
 	config := loadConfig()
 
 	if config.SetDefaultAPISite(name) {
 		fmt.Printf("Set %s as default API site\n", name)
-		
+
 		configFile := getConfigFilePath()
 		err := config.SaveConfig(configFile)
 		if err != nil {
 			log.Fatalf("Error saving config: %v", err)
 		}
-		
+
 		fmt.Println("Configuration saved successfully!")
 
-		fmt.Println("Setting user environment variables...")
-		err = config.SetEnvironmentVariables(name)
-		if err != nil {
-			fmt.Printf("Warning: Failed to set environment variables: %v\n", err)
-			fmt.Println("You may need to set them manually:")
-			site := config.GetAPISiteByName(name)
-			if site != nil {
-				fmt.Printf("  ANTHROPIC_BASE_URL=%s\n", site.BaseURL)
-				fmt.Printf("  ANTHROPIC_AUTH_TOKEN=%s\n", site.Token)
+		if persist {
+			fmt.Println("Persisting environment variables to your shell rc files/registry...")
+			if err := config.SetEnvironmentVariables(name); err != nil {
+				fmt.Printf("Warning: Failed to persist environment variables: %v\n", err)
+			} else {
+				fmt.Println("Environment variables persisted successfully!")
 			}
-		} else {
-			fmt.Println("User environment variables set successfully!")
-			fmt.Println("To apply this change to your current terminal session, run the following command:")
-			site := config.GetAPISiteByName(name)
-			if site != nil {
-				switch runtime.GOOS {
-				case "windows":
-					fmt.Println("\nFor Command Prompt (cmd.exe):")
-					fmt.Printf("set ANTHROPIC_BASE_URL=%s\n", site.BaseURL)
-					fmt.Printf("set ANTHROPIC_AUTH_TOKEN=%s\n", site.Token)
-					fmt.Println("\nFor PowerShell:")
-					fmt.Printf("$env:ANTHROPIC_BASE_URL=\"%s\"\n", site.BaseURL)
-					fmt.Printf("$env:ANTHROPIC_AUTH_TOKEN=\"%s\"\n", site.Token)
-				case "linux", "darwin":
-					fmt.Println("\nFor Bash/Zsh:")
-					fmt.Printf("export ANTHROPIC_BASE_URL=%s\n", site.BaseURL)
-					fmt.Printf("export ANTHROPIC_AUTH_TOKEN=%s\n", site.Token)
-				default:
-					fmt.Println("\nUnsupported OS. Please set the environment variables manually.")
-				}
+		}
+
+		site := config.GetAPISiteByName(name)
+		if site != nil {
+			token, _ := config.tokenStore().Get(name)
+			out, err := formatEnv(envVars{BaseURL: site.BaseURL, AuthToken: token}, defaultShellFormat())
+			if err == nil {
+				fmt.Println("To apply this change to your current shell, run:")
+				fmt.Print(out)
+				fmt.Println("(source `chcc shell-init <shell>` once and this happens automatically on set-default/use)")
 			}
 		}
 	} else {
@@ -266,3 +528,97 @@ func removeAPISite(name string) {
 		}
 	}
 }
+
+func migrateTokens() {
+	config := loadConfig()
+
+	if config.storageMode() == storageKeyring {
+		fmt.Println("Already using keyring storage; nothing to migrate.")
+		return
+	}
+
+	store := keyringTokenStore{}
+	migrated := 0
+	for i, site := range config.APISites {
+		if site.Token == "" {
+			continue
+		}
+		if err := store.Set(site.Name, site.Token); err != nil {
+			log.Fatalf("Error migrating token for %s: %v", site.Name, err)
+		}
+		config.APISites[i].Token = ""
+		migrated++
+	}
+
+	config.Storage = storageKeyring
+
+	configFile := getConfigFilePath()
+	if err := config.SaveConfig(configFile); err != nil {
+		log.Fatalf("Error saving config: %v", err)
+	}
+
+	fmt.Printf("Migrated %d token(s) to the OS keyring.\n", migrated)
+}
+
+func printSiteEnv(name, format string) {
+	config := loadConfig()
+
+	var site *APISite
+	if name != "" {
+		site = config.GetAPISiteByName(name)
+		if site == nil {
+			log.Fatalf("API site '%s' not found", name)
+		}
+	} else {
+		site = config.GetDefaultAPISite()
+		if site == nil {
+			log.Fatal("No default API site set")
+		}
+	}
+
+	token, err := config.tokenStore().Get(site.Name)
+	if err != nil {
+		log.Fatalf("Error reading token: %v", err)
+	}
+
+	if format == "" {
+		format = defaultShellFormat()
+	}
+
+	out, err := formatEnv(envVars{BaseURL: site.BaseURL, AuthToken: token}, format)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(out)
+}
+
+func uninstallShellHooks() {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		log.Fatalf("Error getting user home directory: %v", err)
+	}
+
+	anyRemoved := false
+	for _, rcFile := range []string{homeDir + "/.bashrc", homeDir + "/.zshrc"} {
+		for _, markers := range [][2]string{
+			{chccShellInitBlockStart, chccShellInitBlockEnd},
+			{chccPersistBlockStart, chccPersistBlockEnd},
+		} {
+			removed, err := removeManagedBlock(rcFile, markers[0], markers[1])
+			if err != nil {
+				if !os.IsNotExist(err) {
+					fmt.Printf("Warning: failed to update %s: %v\n", rcFile, err)
+				}
+				continue
+			}
+			if removed {
+				fmt.Printf("Removed chcc-managed block from %s\n", rcFile)
+				anyRemoved = true
+			}
+		}
+	}
+
+	if !anyRemoved {
+		fmt.Println("No chcc-managed block found.")
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProbeResult is the outcome of checking a single API site's health.
+type ProbeResult struct {
+	Name       string     `json:"name"`
+	BaseURL    string     `json:"base_url"`
+	StatusCode int        `json:"status_code,omitempty"`
+	LatencyMs  int64      `json:"latency_ms"`
+	CertExpiry *time.Time `json:"cert_expiry,omitempty"`
+	TokenOK    bool       `json:"token_ok"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// Healthy reports whether the site answered with a 2xx status and no
+// transport-level error occurred.
+func (r ProbeResult) Healthy() bool {
+	return r.Error == "" && r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// Prober performs health/latency checks against API sites by issuing a real
+// HTTPS request to each one's BaseURL.
+type Prober struct {
+	// Timeout bounds a single site's check. Defaults to 5s.
+	Timeout time.Duration
+	// Parallelism caps how many sites are checked at once. Defaults to 8.
+	Parallelism int
+}
+
+// NewProber returns a Prober with sane defaults for interactive use.
+func NewProber() *Prober {
+	return &Prober{Timeout: 5 * time.Second, Parallelism: 8}
+}
+
+// ProbeAll checks every site concurrently (bounded by p.Parallelism) and
+// returns one ProbeResult per site, in the same order as sites. ctx
+// cancellation aborts any checks still in flight.
+func (p *Prober) ProbeAll(ctx context.Context, sites []APISite, store TokenStore) []ProbeResult {
+	results := make([]ProbeResult, len(sites))
+
+	sem := make(chan struct{}, p.parallelism())
+	var wg sync.WaitGroup
+
+	for i, site := range sites {
+		wg.Add(1)
+		go func(i int, site APISite) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				results[i] = ProbeResult{Name: site.Name, BaseURL: site.BaseURL, Error: ctx.Err().Error()}
+				return
+			}
+			defer func() { <-sem }()
+			results[i] = p.probeOne(ctx, site, store)
+		}(i, site)
+	}
+
+	wg.Wait()
+	return results
+}
+
+func (p *Prober) parallelism() int {
+	if p.Parallelism <= 0 {
+		return 8
+	}
+	return p.Parallelism
+}
+
+func (p *Prober) timeout() time.Duration {
+	if p.Timeout <= 0 {
+		return 5 * time.Second
+	}
+	return p.Timeout
+}
+
+func (p *Prober) probeOne(ctx context.Context, site APISite, store TokenStore) ProbeResult {
+	result := ProbeResult{Name: site.Name, BaseURL: site.BaseURL}
+
+	token, err := store.Get(site.Name)
+	if err != nil {
+		token = site.Token
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, p.timeout())
+	defer cancel()
+
+	url := strings.TrimRight(site.BaseURL, "/") + "/v1/models"
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, url, nil)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	req.Header.Set("x-api-key", token)
+	req.Header.Set("anthropic-version", "2023-06-01")
+
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	result.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer resp.Body.Close()
+
+	result.StatusCode = resp.StatusCode
+	result.TokenOK = resp.StatusCode != http.StatusUnauthorized && resp.StatusCode != http.StatusForbidden
+
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		expiry := resp.TLS.PeerCertificates[0].NotAfter
+		result.CertExpiry = &expiry
+	}
+
+	return result
+}
+
+// renderProbeResults prints probe results as a human-readable table or as
+// JSON for CI consumption.
+func renderProbeResults(results []ProbeResult, format string) error {
+	switch format {
+	case "", outputTable:
+		renderProbeResultsTable(results)
+		return nil
+	case outputJSON:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(results)
+	default:
+		return fmt.Errorf("unknown output format %q (want table or json)", format)
+	}
+}
+
+func renderProbeResultsTable(results []ProbeResult) {
+	for _, r := range results {
+		status := "OK"
+		if !r.Healthy() {
+			status = "FAIL"
+		}
+		fmt.Printf("[%s] %s (%s)\n", status, r.Name, r.BaseURL)
+		if r.Error != "" {
+			fmt.Printf("      error: %s\n", r.Error)
+			continue
+		}
+		fmt.Printf("      status: %d, latency: %dms, token accepted: %t\n", r.StatusCode, r.LatencyMs, r.TokenOK)
+		if r.CertExpiry != nil {
+			fmt.Printf("      cert expires: %s\n", r.CertExpiry.Format(time.RFC3339))
+		}
+	}
+}
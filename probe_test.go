@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProbeResultHealthy(t *testing.T) {
+	cases := []struct {
+		name   string
+		result ProbeResult
+		want   bool
+	}{
+		{"2xx with no error", ProbeResult{StatusCode: 200}, true},
+		{"edge of 2xx range", ProbeResult{StatusCode: 299}, true},
+		{"4xx", ProbeResult{StatusCode: 401}, false},
+		{"3xx", ProbeResult{StatusCode: 301}, false},
+		{"transport error even with 2xx status", ProbeResult{StatusCode: 200, Error: "timeout"}, false},
+	}
+	for _, c := range cases {
+		if got := c.result.Healthy(); got != c.want {
+			t.Errorf("%s: Healthy() = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestProbeAllHealthyAndUnhealthySites(t *testing.T) {
+	okServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer okServer.Close()
+
+	failServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer failServer.Close()
+
+	sites := []APISite{
+		{Name: "ok", BaseURL: okServer.URL, Token: "tok"},
+		{Name: "fail", BaseURL: failServer.URL, Token: "tok"},
+	}
+	store := plaintextTokenStore{config: &Config{APISites: sites}}
+
+	prober := &Prober{Timeout: 0, Parallelism: 0}
+	results := prober.ProbeAll(context.Background(), sites, store)
+
+	if len(results) != 2 {
+		t.Fatalf("ProbeAll returned %d results, want 2", len(results))
+	}
+	if results[0].Name != "ok" || !results[0].Healthy() {
+		t.Errorf("results[0] = %+v, want a healthy result for %q", results[0], "ok")
+	}
+	if results[1].Name != "fail" || results[1].Healthy() {
+		t.Errorf("results[1] = %+v, want an unhealthy result for %q", results[1], "fail")
+	}
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// serveRequest is the line-delimited JSON request understood by `chcc serve`:
+// {"op":"get"} or {"op":"switch","name":"..."}.
+type serveRequest struct {
+	Op   string `json:"op"`
+	Name string `json:"name,omitempty"`
+}
+
+// serveResponse answers a request and is also broadcast unsolicited to every
+// connected client whenever the active site changes.
+type serveResponse struct {
+	Event     string `json:"event,omitempty"`
+	Name      string `json:"name,omitempty"`
+	BaseURL   string `json:"base_url,omitempty"`
+	AuthToken string `json:"auth_token,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// server fans change notifications out to every connection currently parked
+// on `chcc serve`, whether the change came from this process handling a
+// "switch" op or from another `chcc set-default` invocation editing
+// ~/.chcc.yaml directly.
+type server struct {
+	mu      sync.Mutex
+	clients map[chan serveResponse]struct{}
+}
+
+func newServer() *server {
+	return &server{clients: make(map[chan serveResponse]struct{})}
+}
+
+func (s *server) subscribe() chan serveResponse {
+	ch := make(chan serveResponse, 8)
+	s.mu.Lock()
+	s.clients[ch] = struct{}{}
+	s.mu.Unlock()
+	return ch
+}
+
+func (s *server) unsubscribe(ch chan serveResponse) {
+	s.mu.Lock()
+	delete(s.clients, ch)
+	s.mu.Unlock()
+	close(ch)
+}
+
+func (s *server) broadcast(resp serveResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.clients {
+		select {
+		case ch <- resp:
+		default:
+			// Slow client; drop the notification rather than block the
+			// whole server on it.
+		}
+	}
+}
+
+// serve starts the `chcc serve` listener. socketPath overrides
+// Config.ListenSocket/the default when non-empty. The transport is a Unix
+// domain socket on Unix and a named pipe on Windows; see platformListen in
+// serve_unix.go/serve_windows.go.
+func serve(socketPath string) {
+	config := loadConfig()
+
+	if socketPath == "" {
+		socketPath = config.ListenSocket
+	}
+	if socketPath == "" {
+		socketPath = defaultSocketPath()
+	}
+
+	srv := newServer()
+
+	listener := platformListen(socketPath)
+	defer listener.Close()
+	go acceptLoop(listener, srv)
+	fmt.Printf("chcc serve listening on %s\n", socketPath)
+
+	if config.ListenAddr != "" {
+		tcpListener, err := net.Listen("tcp", config.ListenAddr)
+		if err != nil {
+			log.Fatalf("Error listening on %s: %v", config.ListenAddr, err)
+		}
+		defer tcpListener.Close()
+		go acceptLoop(tcpListener, srv)
+		fmt.Printf("chcc serve listening on tcp %s\n", config.ListenAddr)
+	}
+
+	watchConfigFile(srv)
+}
+
+func acceptLoop(listener net.Listener, srv *server) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			log.Printf("Error accepting connection: %v", err)
+			continue
+		}
+		go handleConn(conn, srv)
+	}
+}
+
+func handleConn(conn net.Conn, srv *server) {
+	defer conn.Close()
+
+	updates := srv.subscribe()
+	defer srv.unsubscribe(updates)
+
+	requests := make(chan serveRequest)
+	go func() {
+		defer close(requests)
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			var req serveRequest
+			if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+				json.NewEncoder(conn).Encode(serveResponse{Error: fmt.Sprintf("invalid request: %v", err)})
+				continue
+			}
+			requests <- req
+		}
+	}()
+
+	encoder := json.NewEncoder(conn)
+	for {
+		select {
+		case req, ok := <-requests:
+			if !ok {
+				return
+			}
+			if err := encoder.Encode(handleRequest(req, srv)); err != nil {
+				return
+			}
+		case resp, ok := <-updates:
+			if !ok {
+				return
+			}
+			if err := encoder.Encode(resp); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// handleRequest answers req for the connection that sent it. For "switch",
+// it also directly broadcasts the change to every connected client via srv,
+// including the one that sent the request (which so gets the "switched"
+// event twice: as its reply and as a broadcast), rather than relying solely
+// on watchConfigFile noticing this process's own write to ~/.chcc.yaml
+// (that fsnotify-driven path is there for changes made by a different chcc
+// process, though it also reports this write as "default-changed").
+func handleRequest(req serveRequest, srv *server) serveResponse {
+	config := loadConfig()
+
+	switch req.Op {
+	case "get":
+		site := config.GetDefaultAPISite()
+		if site == nil {
+			return serveResponse{Error: "no default API site set"}
+		}
+		token, err := config.tokenStore().Get(site.Name)
+		if err != nil {
+			return serveResponse{Error: err.Error()}
+		}
+		return serveResponse{Name: site.Name, BaseURL: site.BaseURL, AuthToken: token}
+
+	case "switch":
+		if !config.SetDefaultAPISite(req.Name) {
+			return serveResponse{Error: fmt.Sprintf("API site '%s' not found", req.Name)}
+		}
+		if err := config.SaveConfig(getConfigFilePath()); err != nil {
+			return serveResponse{Error: err.Error()}
+		}
+		token, _ := config.tokenStore().Get(req.Name)
+		resp := serveResponse{Event: "switched", Name: req.Name, BaseURL: config.GetAPISiteByName(req.Name).BaseURL, AuthToken: token}
+		srv.broadcast(resp)
+		return resp
+
+	default:
+		return serveResponse{Error: fmt.Sprintf("unknown op '%s'", req.Op)}
+	}
+}
+
+// watchConfigFile uses fsnotify to detect ~/.chcc.yaml changes made by other
+// chcc processes (e.g. `chcc set-default` run from another shell) and
+// broadcasts the new default to every client parked on `chcc serve`. It
+// returns only once the watcher's event channel is closed (watcher errors
+// are not read and do not end it), and if the watcher cannot be set up it
+// logs a warning and blocks forever, so callers should run it last.
+func watchConfigFile(srv *server) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Printf("Warning: config file watcher disabled: %v", err)
+		select {}
+	}
+	defer watcher.Close()
+
+	configFile := getConfigFilePath()
+	if err := watcher.Add(filepath.Dir(configFile)); err != nil {
+		log.Printf("Warning: config file watcher disabled: %v", err)
+		select {}
+	}
+
+	for event := range watcher.Events {
+		if filepath.Clean(event.Name) != filepath.Clean(configFile) {
+			continue
+		}
+		if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+			continue
+		}
+
+		config, err := LoadConfig(configFile)
+		if err != nil {
+			continue
+		}
+		site := config.GetDefaultAPISite()
+		if site == nil {
+			continue
+		}
+		token, _ := config.tokenStore().Get(site.Name)
+		srv.broadcast(serveResponse{Event: "default-changed", Name: site.Name, BaseURL: site.BaseURL, AuthToken: token})
+	}
+}
@@ -0,0 +1,36 @@
+//go:build !windows
+
+package main
+
+import (
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// defaultSocketPath returns the Unix domain socket path `chcc serve` listens
+// on when Config.ListenSocket is not set.
+func defaultSocketPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		log.Fatalf("Error getting user home directory: %v", err)
+	}
+	return filepath.Join(homeDir, ".chcc.sock")
+}
+
+// platformListen opens the Unix domain socket `chcc serve` listens on,
+// removing any stale socket file left behind by a previous run and
+// restricting it to the owning user (0600).
+func platformListen(socketPath string) net.Listener {
+	os.Remove(socketPath)
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		log.Fatalf("Error listening on %s: %v", socketPath, err)
+	}
+	if err := os.Chmod(socketPath, 0600); err != nil {
+		log.Fatalf("Error setting socket permissions on %s: %v", socketPath, err)
+	}
+	return listener
+}
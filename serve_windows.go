@@ -0,0 +1,29 @@
+//go:build windows
+
+package main
+
+import (
+	"log"
+	"net"
+
+	winio "github.com/Microsoft/go-winio"
+)
+
+// defaultSocketPath returns the named pipe path `chcc serve` listens on
+// when Config.ListenSocket is not set.
+func defaultSocketPath() string {
+	return `\\.\pipe\chcc`
+}
+
+// platformListen opens the Windows named pipe `chcc serve` listens on, with
+// a security descriptor that only grants access to the owning user,
+// mirroring the 0600 socket permissions used on Unix.
+func platformListen(pipePath string) net.Listener {
+	listener, err := winio.ListenPipe(pipePath, &winio.PipeConfig{
+		SecurityDescriptor: "D:P(A;;GA;;;OW)",
+	})
+	if err != nil {
+		log.Fatalf("Error listening on %s: %v", pipePath, err)
+	}
+	return listener
+}
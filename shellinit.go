@@ -0,0 +1,68 @@
+package main
+
+import "fmt"
+
+// chccShellInitBlockStart and chccShellInitBlockEnd delimit the chcc()
+// wrapper block that `chcc shell-init` prints, so `chcc uninstall-shell-hooks`
+// can find and remove it. See chccPersistBlockStart/End in config.go for why
+// this is a separate marker pair from the persisted-export block.
+const (
+	chccShellInitBlockStart = "# >>> chcc:shell-init >>>"
+	chccShellInitBlockEnd   = "# <<< chcc:shell-init <<<"
+)
+
+// shellInitScript returns the snippet chcc shell-init prints for the given
+// shell: a chcc() wrapper that calls `chcc env` and applies the result to
+// the current shell right after `set-default`/`use`, instead of chcc
+// mutating rc files directly. Source the output once, e.g.
+// `chcc shell-init zsh >> ~/.zshrc`.
+func shellInitScript(shell string) (string, error) {
+	switch shell {
+	case "bash", "zsh":
+		return fmt.Sprintf(`%s
+chcc() {
+  command chcc "$@"
+  local exit_code=$?
+  if [ "$1" = "set-default" ] || [ "$1" = "use" ]; then
+    eval "$(command chcc env --format %s 2>/dev/null)"
+  fi
+  return $exit_code
+}
+%s
+`, chccShellInitBlockStart, shell, chccShellInitBlockEnd), nil
+
+	case "fish":
+		return fmt.Sprintf(`%s
+function chcc
+    command chcc $argv
+    set -l exit_code $status
+    if test "$argv[1]" = "set-default" -o "$argv[1]" = "use"
+        command chcc env --format fish | source
+    end
+    return $exit_code
+end
+%s
+`, chccShellInitBlockStart, chccShellInitBlockEnd), nil
+
+	case "powershell":
+		return fmt.Sprintf(`%s
+function chcc {
+    & (Get-Command -CommandType Application chcc) @args
+    if ($args.Count -gt 0 -and ($args[0] -eq "set-default" -or $args[0] -eq "use")) {
+        & (Get-Command -CommandType Application chcc) env --format powershell | Out-String | Invoke-Expression
+    }
+}
+%s
+`, chccShellInitBlockStart, chccShellInitBlockEnd), nil
+
+	case "cmd":
+		return fmt.Sprintf(`%s
+:: cmd.exe has no function/eval primitive, so apply changes with:
+::   chcc set-default --name foo && chcc env --format cmd > "%%TEMP%%\chcc_env.bat" && call "%%TEMP%%\chcc_env.bat"
+%s
+`, chccShellInitBlockStart, chccShellInitBlockEnd), nil
+
+	default:
+		return "", fmt.Errorf("unknown shell %q (want bash, zsh, fish, powershell, or cmd)", shell)
+	}
+}
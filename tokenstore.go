@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/zalando/go-keyring"
+)
+
+// keyringService namespaces chcc's entries in the OS credential store so
+// they don't collide with other tools using go-keyring.
+const keyringService = "chcc"
+
+const (
+	storageKeyring   = "keyring"
+	storagePlaintext = "plaintext"
+)
+
+// TokenStore abstracts where an API site's token is persisted, so callers
+// don't need to know whether it lives in the OS keyring or in the YAML file
+// itself.
+type TokenStore interface {
+	Get(siteName string) (string, error)
+	Set(siteName, token string) error
+	Delete(siteName string) error
+}
+
+// keyringTokenStore stores tokens in the OS-native credential store
+// (Keychain on macOS, Credential Manager on Windows, Secret Service/libsecret
+// on Linux) instead of writing them to ~/.chcc.yaml.
+type keyringTokenStore struct{}
+
+func (keyringTokenStore) Get(siteName string) (string, error) {
+	token, err := keyring.Get(keyringService, siteName)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token for %q from keyring: %w", siteName, err)
+	}
+	return token, nil
+}
+
+func (keyringTokenStore) Set(siteName, token string) error {
+	if err := keyring.Set(keyringService, siteName, token); err != nil {
+		return fmt.Errorf("failed to store token for %q in keyring: %w", siteName, err)
+	}
+	return nil
+}
+
+func (keyringTokenStore) Delete(siteName string) error {
+	if err := keyring.Delete(keyringService, siteName); err != nil && err != keyring.ErrNotFound {
+		return fmt.Errorf("failed to delete token for %q from keyring: %w", siteName, err)
+	}
+	return nil
+}
+
+// plaintextTokenStore is the fallback for headless environments (CI, SSH
+// sessions without a Secret Service bus, containers) where no OS keyring is
+// available. Tokens are kept directly on the APISite entries, matching
+// chcc's original behavior.
+type plaintextTokenStore struct {
+	config *Config
+}
+
+func (p plaintextTokenStore) Get(siteName string) (string, error) {
+	site := p.config.GetAPISiteByName(siteName)
+	if site == nil {
+		return "", fmt.Errorf("API site %q not found", siteName)
+	}
+	return site.Token, nil
+}
+
+func (p plaintextTokenStore) Set(siteName, token string) error {
+	for i, site := range p.config.APISites {
+		if site.Name == siteName {
+			p.config.APISites[i].Token = token
+			return nil
+		}
+	}
+	return fmt.Errorf("API site %q not found", siteName)
+}
+
+func (p plaintextTokenStore) Delete(siteName string) error {
+	return p.Set(siteName, "")
+}
+
+// storageMode returns the configured token storage backend. An empty
+// Storage field means the config predates this feature, so it defaults to
+// "plaintext" to keep existing ~/.chcc.yaml files working unchanged; run
+// `chcc migrate` to move tokens into the keyring.
+func (c *Config) storageMode() string {
+	if c.Storage == "" {
+		return storagePlaintext
+	}
+	return c.Storage
+}
+
+// tokenStore returns the TokenStore backend selected by c.Storage.
+func (c *Config) tokenStore() TokenStore {
+	if c.storageMode() == storageKeyring {
+		return keyringTokenStore{}
+	}
+	return plaintextTokenStore{config: c}
+}
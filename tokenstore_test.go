@@ -0,0 +1,88 @@
+package main
+
+import "testing"
+
+func TestPlaintextTokenStoreGetSet(t *testing.T) {
+	config := &Config{APISites: []APISite{
+		{Name: "work", Token: "work-token"},
+		{Name: "personal", Token: "personal-token"},
+	}}
+	store := plaintextTokenStore{config: config}
+
+	token, err := store.Get("work")
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	if token != "work-token" {
+		t.Errorf("Get(%q) = %q, want %q", "work", token, "work-token")
+	}
+
+	if err := store.Set("work", "new-token"); err != nil {
+		t.Fatalf("Set returned error: %v", err)
+	}
+	token, err = store.Get("work")
+	if err != nil {
+		t.Fatalf("Get after Set returned error: %v", err)
+	}
+	if token != "new-token" {
+		t.Errorf("Get(%q) after Set = %q, want %q", "work", token, "new-token")
+	}
+
+	// Unrelated sites are untouched.
+	token, err = store.Get("personal")
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	if token != "personal-token" {
+		t.Errorf("Get(%q) = %q, want %q", "personal", token, "personal-token")
+	}
+}
+
+func TestPlaintextTokenStoreGetSetUnknownSite(t *testing.T) {
+	config := &Config{APISites: []APISite{{Name: "work", Token: "work-token"}}}
+	store := plaintextTokenStore{config: config}
+
+	if _, err := store.Get("missing"); err == nil {
+		t.Error("Get for an unknown site should return an error")
+	}
+	if err := store.Set("missing", "token"); err == nil {
+		t.Error("Set for an unknown site should return an error")
+	}
+}
+
+func TestPlaintextTokenStoreDelete(t *testing.T) {
+	config := &Config{APISites: []APISite{{Name: "work", Token: "work-token"}}}
+	store := plaintextTokenStore{config: config}
+
+	if err := store.Delete("work"); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	token, err := store.Get("work")
+	if err != nil {
+		t.Fatalf("Get after Delete returned error: %v", err)
+	}
+	if token != "" {
+		t.Errorf("Get(%q) after Delete = %q, want empty", "work", token)
+	}
+}
+
+func TestStorageMode(t *testing.T) {
+	if mode := (&Config{}).storageMode(); mode != storagePlaintext {
+		t.Errorf("storageMode() for an empty Storage field = %q, want %q", mode, storagePlaintext)
+	}
+	if mode := (&Config{Storage: storageKeyring}).storageMode(); mode != storageKeyring {
+		t.Errorf("storageMode() = %q, want %q", mode, storageKeyring)
+	}
+}
+
+func TestTokenStoreSelectsBackend(t *testing.T) {
+	plaintextConfig := &Config{}
+	if _, ok := plaintextConfig.tokenStore().(plaintextTokenStore); !ok {
+		t.Error("tokenStore() with no Storage set should return a plaintextTokenStore")
+	}
+
+	keyringConfig := &Config{Storage: storageKeyring}
+	if _, ok := keyringConfig.tokenStore().(keyringTokenStore); !ok {
+		t.Error("tokenStore() with Storage=keyring should return a keyringTokenStore")
+	}
+}
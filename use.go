@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// useModel is a minimal bubbletea fuzzy picker over a list of API sites,
+// filtered live as the user types.
+type useModel struct {
+	sites    []APISite
+	filtered []APISite
+	cursor   int
+	query    string
+	chosen   *APISite
+}
+
+func newUseModel(sites []APISite) useModel {
+	return useModel{sites: sites, filtered: sites}
+}
+
+func (m useModel) Init() tea.Cmd { return nil }
+
+func (m useModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEnter:
+		if len(m.filtered) > 0 {
+			site := m.filtered[m.cursor]
+			m.chosen = &site
+		}
+		return m, tea.Quit
+	case tea.KeyUp:
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case tea.KeyDown:
+		if m.cursor < len(m.filtered)-1 {
+			m.cursor++
+		}
+	case tea.KeyBackspace:
+		if len(m.query) > 0 {
+			m.query = m.query[:len(m.query)-1]
+			m.applyFilter()
+		}
+	case tea.KeyRunes:
+		m.query += string(keyMsg.Runes)
+		m.applyFilter()
+	}
+
+	return m, nil
+}
+
+func (m *useModel) applyFilter() {
+	m.filtered = fuzzyFilterSites(m.sites, m.query)
+	if m.cursor >= len(m.filtered) {
+		m.cursor = 0
+	}
+}
+
+func (m useModel) View() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Search: %s\n\n", m.query)
+	for i, site := range m.filtered {
+		cursor := "  "
+		if i == m.cursor {
+			cursor = "> "
+		}
+		fmt.Fprintf(&b, "%s%s", cursor, site.Name)
+		if site.Group != "" {
+			fmt.Fprintf(&b, " [%s]", site.Group)
+		}
+		if len(site.Tags) > 0 {
+			fmt.Fprintf(&b, " (%s)", strings.Join(site.Tags, ", "))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n(type to filter, ↑/↓ to move, enter to select, esc to quit)\n")
+	return b.String()
+}
+
+// fuzzyFilterSites keeps sites whose name, group, and tags, joined with
+// spaces into one string, contain query's characters as a case-insensitive,
+// in-order (but not necessarily contiguous) subsequence, preserving input
+// order; a match may span several of those fields. This is the same kind of
+// matching a fuzzy file picker uses: "ntp" matches "notepad".
+func fuzzyFilterSites(sites []APISite, query string) []APISite {
+	if query == "" {
+		return sites
+	}
+
+	q := strings.ToLower(query)
+	var out []APISite
+	for _, site := range sites {
+		haystack := strings.ToLower(strings.Join(append([]string{site.Name, site.Group}, site.Tags...), " "))
+		if isSubsequence(q, haystack) {
+			out = append(out, site)
+		}
+	}
+	return out
+}
+
+// isSubsequence reports whether every rune of query appears in haystack in
+// the same order, though not necessarily contiguously.
+func isSubsequence(query, haystack string) bool {
+	i := 0
+	runes := []rune(query)
+	if len(runes) == 0 {
+		return true
+	}
+	for _, r := range haystack {
+		if r == runes[i] {
+			i++
+			if i == len(runes) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// runUsePicker launches the interactive picker over sites (already filtered
+// by --tag/--group) and returns the chosen site, or nil if the user quit
+// without selecting one.
+func runUsePicker(sites []APISite) *APISite {
+	if len(sites) == 0 {
+		fmt.Println("No API sites match the given filters.")
+		return nil
+	}
+
+	sort.Slice(sites, func(i, j int) bool { return sites[i].Name < sites[j].Name })
+
+	finalModel, err := tea.NewProgram(newUseModel(sites)).Run()
+	if err != nil {
+		log.Fatalf("Error running picker: %v", err)
+	}
+
+	return finalModel.(useModel).chosen
+}
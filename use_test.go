@@ -0,0 +1,48 @@
+package main
+
+import "testing"
+
+func TestFuzzyFilterSitesSubsequenceMatch(t *testing.T) {
+	sites := []APISite{
+		{Name: "notepad"},
+		{Name: "staging"},
+	}
+
+	got := fuzzyFilterSites(sites, "ntp")
+	if len(got) != 1 || got[0].Name != "notepad" {
+		t.Errorf("fuzzyFilterSites(%q) = %v, want just %q", "ntp", got, "notepad")
+	}
+}
+
+func TestFuzzyFilterSitesEmptyQueryReturnsAll(t *testing.T) {
+	sites := []APISite{{Name: "a"}, {Name: "b"}}
+	got := fuzzyFilterSites(sites, "")
+	if len(got) != len(sites) {
+		t.Errorf("fuzzyFilterSites with an empty query = %v, want all sites unchanged", got)
+	}
+}
+
+func TestFuzzyFilterSitesNoMatch(t *testing.T) {
+	sites := []APISite{{Name: "notepad"}}
+	if got := fuzzyFilterSites(sites, "xyz"); len(got) != 0 {
+		t.Errorf("fuzzyFilterSites(%q) = %v, want no matches", "xyz", got)
+	}
+}
+
+func TestIsSubsequence(t *testing.T) {
+	cases := []struct {
+		query, haystack string
+		want            bool
+	}{
+		{"ntp", "notepad", true},
+		{"pad", "notepad", true},
+		{"dap", "notepad", false},
+		{"", "anything", true},
+		{"x", "", false},
+	}
+	for _, c := range cases {
+		if got := isSubsequence(c.query, c.haystack); got != c.want {
+			t.Errorf("isSubsequence(%q, %q) = %v, want %v", c.query, c.haystack, got, c.want)
+		}
+	}
+}